@@ -0,0 +1,65 @@
+package main
+
+import (
+	"time"
+
+	"dKolesnikov2003/go-notes/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addTitle     string
+	addUseEditor bool
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a new note",
+	Long: `Add a new note. Without --editor, the body is read from stdin:
+type it and press Ctrl+D to finish, or pipe it in from another command.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addNote(addTitle, addUseEditor)
+	},
+}
+
+func init() {
+	addCmd.Flags().StringVarP(&addTitle, "title", "t", "", "note title")
+	addCmd.Flags().BoolVar(&addUseEditor, "editor", false, "edit the note body in $EDITOR instead of reading stdin")
+}
+
+func addNote(title string, useEditor bool) error {
+	text, err := readNoteText(useEditor, "")
+	if err != nil {
+		return err
+	}
+
+	return withVaultLock(func(v Vault, write func(Vault) error) error {
+		n := Note{Timestamp: time.Now(), Title: title}
+		encrypted := v.KDF != nil
+		if encrypted {
+			var err error
+			n.Salt, n.Nonce, n.Ciphertext, err = encryptNote(*v.KDF, text)
+			if err != nil {
+				return err
+			}
+		} else {
+			n.Text = text
+		}
+
+		indexText, indexTags := indexedBody(encrypted, text)
+		id, err := idx.Add(store.Note{
+			Timestamp: n.Timestamp,
+			Title:     n.Title,
+			Text:      indexText,
+			Tags:      indexTags,
+			Encrypted: encrypted,
+		})
+		if err != nil {
+			return err
+		}
+		n.IndexID = id
+
+		v.Notes = append(v.Notes, n)
+		return write(v)
+	})
+}
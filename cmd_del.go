@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var delCmd = &cobra.Command{
+	Use:     "del <number>",
+	Aliases: []string{"delete", "rm"},
+	Short:   "Delete a note selected by number",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return deleteNote(args[0])
+	},
+}
+
+func deleteNote(id string) error {
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return usageErrorf("invalid note number %q", id)
+	}
+	i--
+
+	if err := withVaultLock(func(v Vault, write func(Vault) error) error {
+		if i < 0 || i >= len(v.Notes) {
+			return notFoundErrorf("no note numbered %s", id)
+		}
+		indexID := v.Notes[i].IndexID
+		v.Notes = append(v.Notes[:i], v.Notes[i+1:]...)
+		if err := write(v); err != nil {
+			return err
+		}
+		return idx.Remove(indexID)
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Note %s was deleted successfully\n", id)
+	return nil
+}
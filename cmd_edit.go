@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strconv"
+
+	"dKolesnikov2003/go-notes/store"
+	"github.com/spf13/cobra"
+)
+
+var editUseEditor bool
+
+var editCmd = &cobra.Command{
+	Use:   "edit <number>",
+	Short: "Edit the text of a note selected by number",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editNote(args[0], editUseEditor)
+	},
+}
+
+func init() {
+	editCmd.Flags().BoolVar(&editUseEditor, "editor", false, "edit the note body in $EDITOR instead of reading stdin")
+}
+
+func editNote(id string, useEditor bool) error {
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return usageErrorf("invalid note number %q", id)
+	}
+	i--
+
+	v, err := loadVault()
+	if err != nil {
+		return err
+	}
+	if i < 0 || i >= len(v.Notes) {
+		return notFoundErrorf("no note numbered %s", id)
+	}
+
+	current := v.Notes[i].Text
+	if v.Notes[i].Encrypted() {
+		if v.KDF == nil {
+			return ioErrorf("note %d is encrypted but the vault has no KDF block", i+1)
+		}
+		if current, err = decryptNote(*v.KDF, v.Notes[i]); err != nil {
+			return err
+		}
+	}
+
+	text, err := readNoteText(useEditor, current)
+	if err != nil {
+		return err
+	}
+
+	return withVaultLock(func(v Vault, write func(Vault) error) error {
+		if i < 0 || i >= len(v.Notes) {
+			return notFoundErrorf("no note numbered %s", id)
+		}
+		n := &v.Notes[i]
+		encrypted := n.Encrypted()
+		if encrypted {
+			salt, nonce, ciphertext, err := encryptNote(*v.KDF, text)
+			if err != nil {
+				return err
+			}
+			n.Salt, n.Nonce, n.Ciphertext = salt, nonce, ciphertext
+		} else {
+			n.Text = text
+		}
+
+		if err := write(v); err != nil {
+			return err
+		}
+
+		indexText, indexTags := indexedBody(encrypted, text)
+		return idx.Update(store.Note{
+			ID:        n.IndexID,
+			Timestamp: n.Timestamp,
+			Title:     n.Title,
+			Text:      indexText,
+			Tags:      indexTags,
+			Encrypted: encrypted,
+		})
+	})
+}
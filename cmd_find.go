@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var findCmd = &cobra.Command{
+	Use:   "find <query>",
+	Short: "Run a boolean/phrase search against the title and body of every note",
+	Long: `Run a boolean/phrase search against the title and body of every note.
+
+Encrypted notes are indexed by title and timestamp only: their bodies are
+never written to the index in the clear, so find and --tag can't match
+against an encrypted note's text or #tags.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := currentFormat()
+		if err != nil {
+			return err
+		}
+		return findNotes(args[0], flagTag, flagFrom, flagTo, flagLimit, format)
+	},
+}
+
+func findNotes(query, tag, from, to string, limit int, format outputFormat) error {
+	fromT, err := parseDateFilter(from)
+	if err != nil {
+		return usageErrorf("--from: %v", err)
+	}
+	toT, err := parseDateFilter(to)
+	if err != nil {
+		return usageErrorf("--to: %v", err)
+	}
+
+	results, err := idx.Find(query, strings.ToLower(tag))
+	if err != nil {
+		return err
+	}
+	filtered := filterResults(results, fromT, toT, limit)
+
+	notes, err := loadJSON()
+	if err != nil {
+		return err
+	}
+	positions := indexPositions(notes)
+	entries := make([]listEntry, 0, len(filtered))
+	for _, r := range filtered {
+		if pos, ok := positions[r.ID]; ok {
+			entries = append(entries, resultListEntry(r, pos))
+		}
+	}
+	return renderEntries(entries, format, "No notes match that query.")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"dKolesnikov2003/go-notes/crypto"
+	"github.com/spf13/cobra"
+)
+
+var initEncrypt bool
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Initialize the notes vault",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !initEncrypt {
+			return usageErrorf("init currently only supports --encrypt")
+		}
+		return initEncryptedVault()
+	},
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initEncrypt, "encrypt", false, "bootstrap a fresh encrypted vault")
+}
+
+func initEncryptedVault() error {
+	v, err := loadVault()
+	if err != nil {
+		return err
+	}
+	if v.KDF != nil {
+		return usageErrorf("vault at %s is already encrypted; refusing to re-initialize it", notesPath)
+	}
+	if len(v.Notes) > 0 {
+		return usageErrorf("vault at %s already holds unencrypted notes; --encrypt only bootstraps a fresh vault", notesPath)
+	}
+
+	passphrase, err := promptNewPassphrase()
+	if err != nil {
+		return err
+	}
+	cachedPassphrase = &passphrase
+
+	params := crypto.DefaultKDFParams
+	if err := updateVault(func(v Vault) (Vault, error) {
+		v.KDF = &params
+		return v, nil
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("Initialized an encrypted vault at %s\n", notesPath)
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "Display the list of all saved notes",
+	Long: `Display the list of all saved notes.
+
+--tag is index-backed, and the index never stores an encrypted note's
+#tags (see find --help), so an encrypted note can never show up under
+any --tag. --from and --to still match it normally, since timestamps
+aren't affected by encryption; its preview just always reads [encrypted].`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := currentFormat()
+		if err != nil {
+			return err
+		}
+		return listNotes(flagTag, flagFrom, flagTo, flagLimit, format)
+	},
+}
+
+func listNotes(tag, from, to string, limit int, format outputFormat) error {
+	fromT, err := parseDateFilter(from)
+	if err != nil {
+		return usageErrorf("--from: %v", err)
+	}
+	toT, err := parseDateFilter(to)
+	if err != nil {
+		return usageErrorf("--to: %v", err)
+	}
+
+	if tag != "" || !fromT.IsZero() || !toT.IsZero() {
+		results, err := idx.Find("", strings.ToLower(tag))
+		if err != nil {
+			return err
+		}
+		filtered := filterResults(results, fromT, toT, limit)
+		notes, err := loadJSON()
+		if err != nil {
+			return err
+		}
+		positions := indexPositions(notes)
+		entries := make([]listEntry, 0, len(filtered))
+		for _, r := range filtered {
+			if pos, ok := positions[r.ID]; ok {
+				entries = append(entries, resultListEntry(r, pos))
+			}
+		}
+		return renderEntries(entries, format, "No notes match that filter.")
+	}
+
+	notes, err := loadJSON()
+	if err != nil {
+		return err
+	}
+	if limit > 0 && len(notes) > limit {
+		notes = notes[:limit]
+	}
+	entries := make([]listEntry, 0, len(notes))
+	for i, n := range notes {
+		entries = append(entries, noteListEntry(int64(i+1), n))
+	}
+	return renderEntries(entries, format, "Not a single note has been created yet.")
+}
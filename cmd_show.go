@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <number>",
+	Short: "Show the text of a note selected by number",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := currentFormat()
+		if err != nil {
+			return err
+		}
+		return showNote(args[0], format)
+	},
+}
+
+func showNote(id string, format outputFormat) error {
+	v, err := loadVault()
+	if err != nil {
+		return err
+	}
+
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return usageErrorf("invalid note number %q", id)
+	}
+	i--
+	if i < 0 || i >= len(v.Notes) {
+		return notFoundErrorf("no note numbered %s", id)
+	}
+
+	note := v.Notes[i]
+	text := note.Text
+	if note.Encrypted() {
+		if v.KDF == nil {
+			return ioErrorf("note %d is encrypted but the vault has no KDF block", i+1)
+		}
+		text, err = decryptNote(*v.KDF, note)
+		if err != nil {
+			return err
+		}
+	}
+
+	if format == formatJSON {
+		out := note
+		out.Text = text
+		out.Salt, out.Nonce, out.Ciphertext = nil, nil, nil
+		return printJSON(out)
+	}
+
+	fmt.Printf("%2d. %s  [%s]\n\n%s\n",
+		i+1, note.Timestamp.Format("02/01/2006 15:04"), note.Title, text)
+	return nil
+}
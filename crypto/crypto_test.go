@@ -0,0 +1,40 @@
+package crypto
+
+import "testing"
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatalf("NewSalt: %v", err)
+	}
+	plaintext := []byte("the note body")
+
+	nonce, ciphertext, err := Seal("hunter2", salt, DefaultKDFParams, plaintext)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	got, err := Open("hunter2", salt, nonce, ciphertext, DefaultKDFParams)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if string(got) != string(plaintext) {
+		t.Fatalf("Open returned %q, want %q", got, plaintext)
+	}
+}
+
+func TestOpenWrongPassphrase(t *testing.T) {
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatalf("NewSalt: %v", err)
+	}
+
+	nonce, ciphertext, err := Seal("correct-passphrase", salt, DefaultKDFParams, []byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	if _, err := Open("wrong-passphrase", salt, nonce, ciphertext, DefaultKDFParams); err == nil {
+		t.Fatal("Open succeeded with the wrong passphrase, want an error")
+	}
+}
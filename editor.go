@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// isInteractive reports whether stdin is attached to a terminal.
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// editInEditor opens $EDITOR (falling back to vi) on a tempfile seeded with
+// initial, waits for it to exit, and returns the edited contents — the same
+// flow `git commit` uses for commit messages.
+func editInEditor(initial string) (string, error) {
+	f, err := os.CreateTemp("", "go-notes-*.md")
+	if err != nil {
+		return "", fmt.Errorf("create tempfile: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	if _, err := f.WriteString(initial); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write tempfile: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("close tempfile: %w", err)
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	cmd := exec.Command(editor, path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("run %s: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read tempfile: %w", err)
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// readNoteText reads note text for add/edit: $EDITOR on a tempfile when
+// useEditor is set and stdin is an interactive TTY, otherwise the plain
+// bufio.Scanner loop over (possibly piped) stdin.
+func readNoteText(useEditor bool, initial string) (string, error) {
+	if useEditor && isInteractive() {
+		return editInEditor(initial)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Exit codes for scripting: distinct per failure class so a caller can
+// branch on $? without scraping stderr.
+const (
+	exitGeneral  = 1
+	exitUsage    = 2
+	exitNotFound = 3
+	exitIO       = 4
+	exitParse    = 5
+)
+
+// cliError pairs an error with the exit code main should use for it.
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string { return e.err.Error() }
+func (e *cliError) Unwrap() error { return e.err }
+
+func usageErrorf(format string, a ...interface{}) error {
+	return &cliError{code: exitUsage, err: fmt.Errorf(format, a...)}
+}
+
+func notFoundErrorf(format string, a ...interface{}) error {
+	return &cliError{code: exitNotFound, err: fmt.Errorf(format, a...)}
+}
+
+func ioErrorf(format string, a ...interface{}) error {
+	return &cliError{code: exitIO, err: fmt.Errorf(format, a...)}
+}
+
+func parseErrorf(format string, a ...interface{}) error {
+	return &cliError{code: exitParse, err: fmt.Errorf(format, a...)}
+}
+
+// classifyErr resolves the exit code to use for err: an already-classified
+// cliError is returned as-is, an error bubbled up from the store/storage
+// packages (identifiable by their own "store:"/"storage:" prefix) is
+// treated as an io failure, and everything else is a plain, unclassified
+// failure.
+func classifyErr(err error) *cliError {
+	var ce *cliError
+	if errors.As(err, &ce) {
+		return ce
+	}
+	if msg := err.Error(); strings.Contains(msg, "store:") || strings.Contains(msg, "storage:") {
+		return &cliError{code: exitIO, err: err}
+	}
+	return &cliError{code: exitGeneral, err: err}
+}
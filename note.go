@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"dKolesnikov2003/go-notes/crypto"
+	"dKolesnikov2003/go-notes/store"
+	"dKolesnikov2003/go-notes/storage"
+)
+
+// Note is a single notes.json entry. A plain note carries Text; an
+// encrypted one instead carries Salt, Nonce and Ciphertext, with Text left
+// empty, per the vault's KDF (see Vault.KDF). IndexID is the row this note
+// was assigned in the store index, so edit/del can address it directly
+// instead of assuming the two stores stay positionally aligned.
+type Note struct {
+	Timestamp  time.Time
+	Title      string
+	Text       string `json:",omitempty"`
+	Salt       []byte `json:",omitempty"`
+	Nonce      []byte `json:",omitempty"`
+	Ciphertext []byte `json:",omitempty"`
+	IndexID    int64  `json:",omitempty"`
+}
+
+// Encrypted reports whether n's body is stored sealed rather than as Text.
+func (n Note) Encrypted() bool {
+	return len(n.Ciphertext) > 0
+}
+
+// Vault is the notes.json document: the saved notes plus, once the vault
+// has been initialized with `init --encrypt`, the KDF parameters their
+// bodies are sealed under.
+type Vault struct {
+	KDF   *crypto.KDFParams `json:",omitempty"`
+	Notes []Note
+}
+
+// loadVault reads the full notes.json document under a storage lock.
+func loadVault() (Vault, error) {
+	data, err := storage.Read(notesPath)
+	if err != nil {
+		return Vault{}, err
+	}
+	return parseVault(data)
+}
+
+// parseVault accepts both the current {KDF, Notes} document and the bare
+// note array notes.json used before vaults existed, so old stores keep
+// working without a separate migration step.
+func parseVault(data []byte) (Vault, error) {
+	if len(data) == 0 {
+		return Vault{}, nil
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var notes []Note
+		if err := json.Unmarshal(data, &notes); err != nil {
+			return Vault{}, parseErrorf("parse %s: %v", notesPath, err)
+		}
+		return Vault{Notes: notes}, nil
+	}
+	var v Vault
+	if err := json.Unmarshal(data, &v); err != nil {
+		return Vault{}, parseErrorf("parse %s: %v", notesPath, err)
+	}
+	return v, nil
+}
+
+// updateVault performs a locked, atomic read-modify-write cycle over the
+// notes.json document: fn receives the current vault and returns the vault
+// to persist. Every command that mutates notes.json goes through this (or
+// updateNotes below) so parallel `go-notes` invocations can't corrupt or
+// clobber each other.
+func updateVault(fn func(v Vault) (Vault, error)) error {
+	return storage.Update(notesPath, func(existing []byte) ([]byte, error) {
+		v, err := parseVault(existing)
+		if err != nil {
+			return nil, err
+		}
+		next, err := fn(v)
+		if err != nil {
+			return nil, err
+		}
+		return json.MarshalIndent(next, "", "  ")
+	})
+}
+
+// withVaultLock holds the vault's advisory lock for the duration of fn,
+// handing it the current vault plus a write callback to persist changes.
+// Unlike updateVault, the lock stays held until fn returns, so fn can pair
+// an index mutation (idx.Add/Update/Remove) with the vault write as one
+// critical section — add/edit/del use this instead of updateVault so a
+// concurrent go-notes invocation can never see the two stores out of sync.
+func withVaultLock(fn func(v Vault, write func(Vault) error) error) error {
+	return storage.WithLock(notesPath, func() error {
+		data, err := storage.ReadFile(notesPath)
+		if err != nil {
+			return err
+		}
+		v, err := parseVault(data)
+		if err != nil {
+			return err
+		}
+		write := func(next Vault) error {
+			data, err := json.MarshalIndent(next, "", "  ")
+			if err != nil {
+				return err
+			}
+			return storage.WriteFile(notesPath, data)
+		}
+		return fn(v, write)
+	})
+}
+
+// loadJSON reads just the notes out of the vault, for callers that don't
+// care about encryption.
+func loadJSON() ([]Note, error) {
+	v, err := loadVault()
+	if err != nil {
+		return nil, err
+	}
+	return v.Notes, nil
+}
+
+// updateNotes is updateVault narrowed to the notes slice, for callers that
+// don't touch the KDF block.
+func updateNotes(fn func(notes []Note) ([]Note, error)) error {
+	return updateVault(func(v Vault) (Vault, error) {
+		notes, err := fn(v.Notes)
+		if err != nil {
+			return Vault{}, err
+		}
+		v.Notes = notes
+		return v, nil
+	})
+}
+
+// indexPositions maps each note's IndexID to its 1-based position in
+// notes.json. find and list --tag/--from/--to start from a store.Result,
+// which only knows the index's own row ID; resolving through this map lets
+// them report the same number show/edit/del expect instead of a second,
+// incompatible numbering. A note with no IndexID yet (a pre-migration vault
+// that hasn't been backfilled) simply has no entry, so callers skip it
+// rather than render a number that doesn't resolve to anything.
+func indexPositions(notes []Note) map[int64]int64 {
+	positions := make(map[int64]int64, len(notes))
+	for i, n := range notes {
+		if n.IndexID != 0 {
+			positions[n.IndexID] = int64(i + 1)
+		}
+	}
+	return positions
+}
+
+// firstLine returns the first line of text, truncated to maxLen with an
+// ellipsis if needed, for single-line list previews.
+func firstLine(text string) string {
+	const maxLen = 40
+	line := strings.SplitN(text, "\n", 2)[0]
+	if len(line) > maxLen {
+		line = line[:maxLen] + "..."
+	}
+	return line
+}
+
+// indexedBody returns the text and tags to persist in the search index for
+// a note. An encrypted note's body must never reach notes.db in the clear,
+// so it contributes nothing searchable beyond its title and timestamp,
+// which the index already stores separately.
+func indexedBody(encrypted bool, text string) (string, []string) {
+	if encrypted {
+		return "", nil
+	}
+	return text, store.ExtractTags(text)
+}
+
+// migrateToIndex imports an existing notes.json into the index the first
+// time go-notes runs against an empty index. The JSON file is left in place
+// as the import/export format; each note's assigned index ID is written
+// back onto it so later edit/del calls can address it directly. Against an
+// already-populated index it instead runs the upgrade-path cleanups below.
+func migrateToIndex() error {
+	empty, err := store.Empty(idx)
+	if err != nil {
+		return fmt.Errorf("check index: %w", err)
+	}
+	if !empty {
+		if err := scrubIndexedPlaintext(); err != nil {
+			return err
+		}
+		return backfillIndexIDs()
+	}
+	return updateNotes(func(notes []Note) ([]Note, error) {
+		for i, n := range notes {
+			indexText, indexTags := indexedBody(n.Encrypted(), n.Text)
+			id, err := idx.Add(store.Note{
+				Timestamp: n.Timestamp,
+				Title:     n.Title,
+				Text:      indexText,
+				Tags:      indexTags,
+				Encrypted: n.Encrypted(),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("migrate note %q: %w", n.Title, err)
+			}
+			notes[i].IndexID = id
+		}
+		if err := idx.Commit(); err != nil {
+			return nil, err
+		}
+		return notes, nil
+	})
+}
+
+// scrubIndexedPlaintext cleans up notes.db files written by a version that
+// indexed every note's body in the clear, including encrypted ones. It
+// relies on the index having been populated in the same order as
+// notes.json (true for any index this codebase has ever produced) to line
+// up each vault note with its index row; if the counts have since
+// diverged, there's nothing safe to assume and it leaves the index alone.
+func scrubIndexedPlaintext() error {
+	notes, err := loadJSON()
+	if err != nil {
+		return fmt.Errorf("read %s: %w", notesPath, err)
+	}
+	indexed, err := idx.Find("", "")
+	if err != nil {
+		return fmt.Errorf("scrub index: %w", err)
+	}
+	if len(indexed) != len(notes) {
+		return nil
+	}
+	for i, n := range notes {
+		if !n.Encrypted() {
+			continue
+		}
+		r := indexed[i]
+		if r.Encrypted && r.Text == "" && len(r.Tags) == 0 {
+			continue
+		}
+		if err := idx.Update(store.Note{
+			ID:        r.ID,
+			Timestamp: r.Timestamp,
+			Title:     r.Title,
+			Encrypted: true,
+		}); err != nil {
+			return fmt.Errorf("scrub note %q: %w", n.Title, err)
+		}
+	}
+	return nil
+}
+
+// backfillIndexIDs is a one-time upgrade path for vaults written before
+// Note.IndexID existed: those notes carry the zero value even though the
+// index already has a row for each of them. If every note already has an
+// IndexID this is a no-op, checked with a lock-free read first so the
+// common case (already backfilled) doesn't pay for a locked rewrite of
+// notes.json on every command. Otherwise it falls back to the old
+// positional invariant — the index was populated in the same order as
+// notes.json — and only applies it when the row counts still match, since
+// that's the one case where the guess is safe.
+func backfillIndexIDs() error {
+	notes, err := loadJSON()
+	if err != nil {
+		return err
+	}
+	if !anyMissingIndexID(notes) {
+		return nil
+	}
+	return updateNotes(func(notes []Note) ([]Note, error) {
+		if !anyMissingIndexID(notes) {
+			return notes, nil
+		}
+		indexed, err := idx.Find("", "")
+		if err != nil {
+			return nil, fmt.Errorf("backfill index ids: %w", err)
+		}
+		if len(indexed) != len(notes) {
+			return notes, nil
+		}
+		for i := range notes {
+			if notes[i].IndexID == 0 {
+				notes[i].IndexID = indexed[i].ID
+			}
+		}
+		return notes, nil
+	})
+}
+
+// anyMissingIndexID reports whether any note still carries the zero-value
+// IndexID left behind by a vault written before that field existed.
+func anyMissingIndexID(notes []Note) bool {
+	for _, n := range notes {
+		if n.IndexID == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDateFilter parses a YYYY-MM-DD flag value, returning the zero time
+// if s is empty.
+func parseDateFilter(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// inDateRange reports whether ts falls within [from, to], treating a zero
+// bound as unset.
+func inDateRange(ts, from, to time.Time) bool {
+	if !from.IsZero() && ts.Before(from) {
+		return false
+	}
+	if !to.IsZero() && ts.After(to.Add(24*time.Hour-time.Nanosecond)) {
+		return false
+	}
+	return true
+}
+
+// filterResults narrows results to the given date range and caps the count
+// at limit (0 means unlimited).
+func filterResults(results []store.Result, from, to time.Time, limit int) []store.Result {
+	var out []store.Result
+	for _, r := range results {
+		if inDateRange(r.Timestamp, from, to) {
+			out = append(out, r)
+		}
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
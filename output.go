@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"dKolesnikov2003/go-notes/store"
+)
+
+// outputFormat is the validated value of --format (or --json, a shorthand
+// for --format=json).
+type outputFormat string
+
+const (
+	formatText  outputFormat = "text"
+	formatJSON  outputFormat = "json"
+	formatTable outputFormat = "table"
+)
+
+// resolveFormat validates --format and folds the --json shorthand into it.
+func resolveFormat(format string, jsonFlag bool) (outputFormat, error) {
+	if jsonFlag {
+		format = string(formatJSON)
+	}
+	if format == "" {
+		format = string(formatText)
+	}
+	switch outputFormat(format) {
+	case formatText, formatJSON, formatTable:
+		return outputFormat(format), nil
+	default:
+		return "", usageErrorf("--format must be one of text, json, table (got %q)", format)
+	}
+}
+
+// currentFormat resolves the format for the command currently running.
+func currentFormat() (outputFormat, error) {
+	return resolveFormat(flagFormat, flagJSON)
+}
+
+// listEntry is the machine-readable shape list and find emit in JSON mode.
+type listEntry struct {
+	ID        int64     `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title"`
+	Preview   string    `json:"preview"`
+	Snippet   string    `json:"snippet,omitempty"`
+	Tags      []string  `json:"tags"`
+	ByteLen   int       `json:"byteLen"`
+}
+
+// noteListEntry builds a listEntry from a plain vault note, numbered by its
+// 1-based position.
+func noteListEntry(position int64, note Note) listEntry {
+	preview := firstLine(note.Text)
+	tags := store.ExtractTags(note.Text)
+	byteLen := len(note.Text)
+	if note.Encrypted() {
+		preview = "[encrypted]"
+		tags = nil
+		byteLen = 0
+	}
+	return listEntry{
+		ID:        position,
+		Timestamp: note.Timestamp,
+		Title:     note.Title,
+		Preview:   preview,
+		Tags:      tags,
+		ByteLen:   byteLen,
+	}
+}
+
+// resultListEntry builds a listEntry from an index search result, numbered
+// by position — the note's 1-based position in notes.json, resolved via
+// indexPositions — rather than the index's own row ID, so the number this
+// prints is the same one show/edit/del expect. An encrypted note's body was
+// never indexed (see indexedBody), so Text and Snippet are always empty for
+// it — render the same [encrypted] placeholder noteListEntry uses rather
+// than showing what would look like an empty note.
+func resultListEntry(r store.Result, position int64) listEntry {
+	if r.Encrypted {
+		return listEntry{
+			ID:        position,
+			Timestamp: r.Timestamp,
+			Title:     r.Title,
+			Preview:   "[encrypted]",
+		}
+	}
+	return listEntry{
+		ID:        position,
+		Timestamp: r.Timestamp,
+		Title:     r.Title,
+		Preview:   firstLine(r.Text),
+		Snippet:   r.Snippet,
+		Tags:      r.Tags,
+		ByteLen:   len(r.Text),
+	}
+}
+
+// renderEntries prints entries in the given format: a JSON array (always,
+// even when empty), an aligned table, or the existing human-readable
+// listing, falling back to emptyMsg when there's nothing to show.
+func renderEntries(entries []listEntry, format outputFormat, emptyMsg string) error {
+	if format == formatJSON {
+		if entries == nil {
+			entries = []listEntry{}
+		}
+		return printJSON(entries)
+	}
+	if len(entries) == 0 {
+		fmt.Println(emptyMsg)
+		return nil
+	}
+	if format == formatTable {
+		printTable(entries)
+		return nil
+	}
+	for _, e := range entries {
+		text := e.Snippet
+		if text == "" {
+			text = e.Preview
+		}
+		fmt.Printf("%2d. %s  [%s]\n    %s\n\n",
+			e.ID, e.Timestamp.Format("02/01/2006 15:04"), e.Title, text)
+	}
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+func printTable(entries []listEntry) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDATE\tTITLE\tPREVIEW")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n",
+			e.ID, e.Timestamp.Format("2006-01-02"), tableCell(e.Title), tableCell(e.Preview))
+	}
+	tw.Flush()
+}
+
+// tableCell strips tabs and newlines from a value bound for a tabwriter
+// cell so embedded whitespace in a title or preview can't be mistaken for
+// column or row boundaries.
+func tableCell(s string) string {
+	r := strings.NewReplacer("\t", " ", "\n", " ")
+	return r.Replace(s)
+}
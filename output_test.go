@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"dKolesnikov2003/go-notes/store"
+)
+
+func TestResolveFormat(t *testing.T) {
+	cases := []struct {
+		format  string
+		json    bool
+		want    outputFormat
+		wantErr bool
+	}{
+		{format: "", json: false, want: formatText},
+		{format: "", json: true, want: formatJSON},
+		{format: "table", json: false, want: formatTable},
+		{format: "json", json: false, want: formatJSON},
+		{format: "yaml", json: false, wantErr: true},
+	}
+	for _, c := range cases {
+		got, err := resolveFormat(c.format, c.json)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("resolveFormat(%q, %v): want error, got %q", c.format, c.json, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("resolveFormat(%q, %v): %v", c.format, c.json, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("resolveFormat(%q, %v) = %q, want %q", c.format, c.json, got, c.want)
+		}
+	}
+}
+
+func TestResultListEntryUsesPosition(t *testing.T) {
+	// find/list --tag must report the same number show/edit/del expect —
+	// the note's vault position, not the index's own row ID. A result
+	// whose underlying index row ID differs from its vault position is
+	// exactly the case that broke before resultListEntry took position as
+	// an explicit argument instead of reading r.ID.
+	r := store.Result{
+		Note: store.Note{
+			ID:        99,
+			Timestamp: time.Now(),
+			Title:     "Groceries",
+			Text:      "buy milk #home",
+			Tags:      []string{"home"},
+		},
+	}
+	entry := resultListEntry(r, 1)
+	if entry.ID != 1 {
+		t.Fatalf("resultListEntry ID = %d, want 1 (the vault position), not the index row ID %d", entry.ID, r.ID)
+	}
+}
+
+func TestResultListEntryEncrypted(t *testing.T) {
+	r := store.Result{
+		Note: store.Note{
+			ID:        1,
+			Timestamp: time.Now(),
+			Title:     "Secret",
+			Encrypted: true,
+		},
+	}
+	entry := resultListEntry(r, 1)
+	if entry.Preview != "[encrypted]" {
+		t.Errorf("Preview = %q, want [encrypted]", entry.Preview)
+	}
+	if entry.ByteLen != 0 || entry.Tags != nil {
+		t.Errorf("encrypted entry leaked ByteLen/Tags: %+v", entry)
+	}
+}
+
+func TestNoteListEntryPosition(t *testing.T) {
+	n := Note{Timestamp: time.Now(), Title: "Work", Text: "finish report #work"}
+	entry := noteListEntry(3, n)
+	if entry.ID != 3 {
+		t.Fatalf("ID = %d, want 3", entry.ID)
+	}
+	if len(entry.Tags) != 1 || entry.Tags[0] != "work" {
+		t.Errorf("Tags = %v, want [work]", entry.Tags)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"dKolesnikov2003/go-notes/crypto"
+	"golang.org/x/term"
+)
+
+// cachedPassphrase holds the vault passphrase for the lifetime of the
+// process once it's been read, so a command touching several encrypted
+// notes (e.g. a future `find` over decrypted bodies) only prompts once.
+var cachedPassphrase *string
+
+// getPassphrase returns the vault passphrase, prompting once via a
+// no-echo terminal read and caching the result.
+func getPassphrase() (string, error) {
+	if cachedPassphrase != nil {
+		return *cachedPassphrase, nil
+	}
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	p := string(b)
+	cachedPassphrase = &p
+	return p, nil
+}
+
+// promptNewPassphrase asks for a new passphrase twice and confirms the two
+// entries match, for `init --encrypt`.
+func promptNewPassphrase() (string, error) {
+	fmt.Fprint(os.Stderr, "New passphrase: ")
+	p1, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	fmt.Fprint(os.Stderr, "Confirm passphrase: ")
+	p2, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	if string(p1) != string(p2) {
+		return "", fmt.Errorf("passphrases did not match")
+	}
+	return string(p1), nil
+}
+
+// encryptNote seals text under the vault's KDF params with a fresh per-note
+// salt and nonce.
+func encryptNote(params crypto.KDFParams, text string) (salt, nonce, ciphertext []byte, err error) {
+	passphrase, err := getPassphrase()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	salt, err = crypto.NewSalt()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	nonce, ciphertext, err = crypto.Seal(passphrase, salt, params, []byte(text))
+	return salt, nonce, ciphertext, err
+}
+
+// decryptNote opens a note's ciphertext under the vault's KDF params.
+func decryptNote(params crypto.KDFParams, n Note) (string, error) {
+	passphrase, err := getPassphrase()
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := crypto.Open(passphrase, n.Salt, n.Nonce, n.Ciphertext, params)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
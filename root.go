@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"dKolesnikov2003/go-notes/store"
+	"dKolesnikov2003/go-notes/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	notesPath string
+	idx       store.NoteIndex
+
+	flagTag    string
+	flagFrom   string
+	flagTo     string
+	flagJSON   bool
+	flagFormat string
+	flagLimit  int
+)
+
+var rootCmd = &cobra.Command{
+	Use:           "go-notes",
+	Short:         "A small command-line notebook",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := currentFormat(); err != nil {
+			return err
+		}
+
+		var err error
+		notesPath, err = getNotesPath()
+		if err != nil {
+			return err
+		}
+		if err := storage.Recover(notesPath, json.Valid); err != nil {
+			return err
+		}
+		indexPath := filepath.Join(filepath.Dir(notesPath), "notes.db")
+		idx, err = store.Open(indexPath)
+		if err != nil {
+			return err
+		}
+		return migrateToIndex()
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		return idx.Close()
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flagTag, "tag", "", "filter by #tag")
+	rootCmd.PersistentFlags().StringVar(&flagFrom, "from", "", "only notes on/after this date (YYYY-MM-DD)")
+	rootCmd.PersistentFlags().StringVar(&flagTo, "to", "", "only notes on/before this date (YYYY-MM-DD)")
+	rootCmd.PersistentFlags().BoolVar(&flagJSON, "json", false, "shorthand for --format=json")
+	rootCmd.PersistentFlags().StringVar(&flagFormat, "format", "text", "output format: text|json|table")
+	rootCmd.PersistentFlags().IntVar(&flagLimit, "limit", 0, "limit the number of results (0 = unlimited)")
+
+	rootCmd.AddCommand(addCmd, listCmd, showCmd, delCmd, editCmd, findCmd, initCmd)
+}
+
+func getNotesPath() (string, error) {
+	xdgData := os.Getenv("XDG_DATA_HOME")
+	if xdgData == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		xdgData = filepath.Join(home, ".local", "share")
+	}
+	dir := filepath.Join(xdgData, "go-notes")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "notes.json"), nil
+}
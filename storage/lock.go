@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+)
+
+type fileLock struct {
+	f *os.File
+}
+
+func acquireLock(path string) (*fileLock, error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("storage: open lock file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("storage: acquire lock on %s: %w", path, err)
+	}
+	return &fileLock{f: f}, nil
+}
+
+func (l *fileLock) release() error {
+	defer l.f.Close()
+	return unlockFile(l.f)
+}
@@ -0,0 +1,115 @@
+// Package storage makes read-modify-write cycles against a JSON file safe
+// across concurrent go-notes invocations (parallel shell scripts, cron,
+// etc). Every Update takes an OS advisory lock on a path+".lock" sidecar
+// for the duration of the cycle, and writes land via a path+".tmp" +
+// os.Rename so a reader never observes a half-written file.
+package storage
+
+import (
+	"fmt"
+	"os"
+)
+
+// WithLock holds path's advisory lock for the duration of fn. It's the
+// primitive Update and Read are built on; reach for it directly when a
+// caller needs to pair a read or write of path with some other operation
+// (e.g. a second store's own mutation) as one critical section. fn must
+// use ReadFile/WriteFile, not Update/Read, to touch path — those acquire
+// their own lock on the same path and would deadlock against the one
+// WithLock is already holding.
+func WithLock(path string, fn func() error) error {
+	lock, err := acquireLock(path)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+
+	return fn()
+}
+
+// ReadFile reads path's current contents with no locking of its own. Only
+// call this inside a WithLock(path, ...) closure; elsewhere use Read.
+// A missing file reads as (nil, nil).
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// WriteFile atomically replaces path's contents with no locking of its
+// own. Only call this inside a WithLock(path, ...) closure; elsewhere use
+// Update.
+func WriteFile(path string, data []byte) error {
+	return atomicWrite(path, data)
+}
+
+// Update performs a locked read-modify-write cycle against path: it takes
+// an exclusive advisory lock on path+".lock", reads the current contents
+// (nil if path doesn't exist yet), passes them to fn, and atomically
+// replaces path with whatever fn returns.
+func Update(path string, fn func(existing []byte) ([]byte, error)) error {
+	return WithLock(path, func() error {
+		existing, err := ReadFile(path)
+		if err != nil {
+			return err
+		}
+		next, err := fn(existing)
+		if err != nil {
+			return err
+		}
+		return WriteFile(path, next)
+	})
+}
+
+// Read performs a locked read of path, for callers that don't mutate it but
+// still want a consistent view while another go-notes invocation might be
+// mid-write. A missing file reads as (nil, nil).
+func Read(path string) ([]byte, error) {
+	var data []byte
+	err := WithLock(path, func() error {
+		var err error
+		data, err = ReadFile(path)
+		return err
+	})
+	return data, err
+}
+
+// Recover inspects the path+".tmp" sidecar left behind by an Update call
+// that crashed before completing. If it exists and satisfies valid, it's
+// promoted over path on the assumption the crash happened between the
+// write and the rename; otherwise it's a half-written file and is
+// discarded. Callers run this once at startup, before any Update or Read.
+func Recover(path string, valid func([]byte) bool) error {
+	tmp := path + ".tmp"
+	data, err := os.ReadFile(tmp)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("storage: read stray %s: %w", tmp, err)
+	}
+
+	if valid(data) {
+		if err := os.Rename(tmp, path); err != nil {
+			return fmt.Errorf("storage: recover %s: %w", tmp, err)
+		}
+		return nil
+	}
+	return os.Remove(tmp)
+}
+
+func atomicWrite(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("storage: write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("storage: rename %s to %s: %w", tmp, path, err)
+	}
+	return nil
+}
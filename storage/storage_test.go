@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestUpdateInterleaving(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := Update(path, func(existing []byte) ([]byte, error) {
+				count := 0
+				if len(existing) > 0 {
+					var err error
+					count, err = strconv.Atoi(string(existing))
+					if err != nil {
+						return nil, err
+					}
+				}
+				return []byte(strconv.Itoa(count + 1)), nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	got, err := strconv.Atoi(string(data))
+	if err != nil {
+		t.Fatalf("parse final count %q: %v", data, err)
+	}
+	if got != n {
+		t.Fatalf("final count = %d, want %d (a lost update means Update isn't serializing concurrent callers)", got, n)
+	}
+}
+
+func TestRecoverPromotesValidTmp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	if err := os.WriteFile(path+".tmp", []byte("new contents"), 0644); err != nil {
+		t.Fatalf("write tmp: %v", err)
+	}
+
+	if err := Recover(path, func(data []byte) bool { return string(data) == "new contents" }); err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(data) != "new contents" {
+		t.Fatalf("path contents = %q, want %q", data, "new contents")
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf(".tmp sidecar still exists after a successful recover")
+	}
+}
+
+func TestRecoverDiscardsCorruptTmp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	if err := os.WriteFile(path, []byte("old contents"), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := os.WriteFile(path+".tmp", []byte("half-writ"), 0644); err != nil {
+		t.Fatalf("write tmp: %v", err)
+	}
+
+	if err := Recover(path, func(data []byte) bool { return false }); err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(data) != "old contents" {
+		t.Fatalf("path contents = %q, want untouched %q", data, "old contents")
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf(".tmp sidecar still exists after a corrupt recover, want it discarded")
+	}
+}
+
+func TestRecoverNoTmpIsNoop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	if err := Recover(path, func(data []byte) bool { return true }); err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Recover created %s out of nothing", path)
+	}
+}
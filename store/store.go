@@ -0,0 +1,276 @@
+// Package store provides an indexed, searchable backend for notes, backed by
+// SQLite FTS5. It sits alongside the plain notes.json file: the JSON file
+// remains the import/export format, while the index is what `find` and
+// `--tag` queries run against. The approach mirrors the note index used by
+// zk (github.com/zk-org/zk).
+//
+// The index is plaintext on disk, so callers must never pass an encrypted
+// note's body or tags through Add/Update — only its title and timestamp.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Note is a single indexed note. Encrypted marks a note whose vault entry
+// is sealed: for those, callers must leave Text and Tags empty (see the
+// package doc comment), and Find reports Encrypted back so callers can
+// render a placeholder instead of an empty body.
+type Note struct {
+	ID        int64
+	Timestamp time.Time
+	Title     string
+	Text      string
+	Tags      []string
+	Encrypted bool
+}
+
+// Result is a Note matched by Find, with a highlighted snippet of the match.
+type Result struct {
+	Note
+	Snippet string
+}
+
+// NoteIndex is a searchable, taggable store of notes.
+type NoteIndex interface {
+	// Add inserts a new note and returns its assigned ID.
+	Add(n Note) (int64, error)
+	// Update replaces the title, text and tags of an existing note.
+	Update(n Note) error
+	// Remove deletes a note by ID.
+	Remove(id int64) error
+	// Find runs a boolean/phrase FTS5 query against title+body, optionally
+	// narrowed to notes carrying tag. An empty query with a non-empty tag
+	// lists every note under that tag without computing a snippet.
+	Find(query, tag string) ([]Result, error)
+	// Commit flushes any pending writes. Callers that only ever use Add,
+	// Update and Remove don't need it; it exists so future transactional
+	// backends (batched imports, say) have somewhere to flush to.
+	Commit() error
+	// Close releases the underlying database handle.
+	Close() error
+}
+
+type sqliteIndex struct {
+	db *sql.DB
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS notes (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp DATETIME NOT NULL,
+	title     TEXT NOT NULL,
+	text      TEXT NOT NULL,
+	encrypted BOOLEAN NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+	note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+	tag     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tags_tag ON tags(tag);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts5(
+	title, text, content='notes', content_rowid='id'
+);
+
+CREATE TRIGGER IF NOT EXISTS notes_ai AFTER INSERT ON notes BEGIN
+	INSERT INTO notes_fts(rowid, title, text) VALUES (new.id, new.title, new.text);
+END;
+CREATE TRIGGER IF NOT EXISTS notes_ad AFTER DELETE ON notes BEGIN
+	INSERT INTO notes_fts(notes_fts, rowid, title, text) VALUES ('delete', old.id, old.title, old.text);
+END;
+CREATE TRIGGER IF NOT EXISTS notes_au AFTER UPDATE ON notes BEGIN
+	INSERT INTO notes_fts(notes_fts, rowid, title, text) VALUES ('delete', old.id, old.title, old.text);
+	INSERT INTO notes_fts(rowid, title, text) VALUES (new.id, new.title, new.text);
+END;
+`
+
+// Open creates (if necessary) and opens the SQLite index at path, applying
+// the schema migration.
+func Open(path string) (NoteIndex, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("store: open %s: %w", path, err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("store: migrate %s: %w", path, err)
+	}
+	// notes tables created before the encrypted column existed need it
+	// added explicitly; CREATE TABLE IF NOT EXISTS above is a no-op for them.
+	if _, err := db.Exec(`ALTER TABLE notes ADD COLUMN encrypted BOOLEAN NOT NULL DEFAULT 0`); err != nil &&
+		!strings.Contains(err.Error(), "duplicate column") {
+		db.Close()
+		return nil, fmt.Errorf("store: migrate %s: %w", path, err)
+	}
+	return &sqliteIndex{db: db}, nil
+}
+
+// Empty reports whether the index has no notes yet, which main uses to
+// decide whether a one-time migration from notes.json is needed.
+func Empty(idx NoteIndex) (bool, error) {
+	si, ok := idx.(*sqliteIndex)
+	if !ok {
+		return false, fmt.Errorf("store: Empty called on unsupported NoteIndex")
+	}
+	var n int
+	if err := si.db.QueryRow(`SELECT COUNT(1) FROM notes`).Scan(&n); err != nil {
+		return false, err
+	}
+	return n == 0, nil
+}
+
+func (s *sqliteIndex) Add(n Note) (int64, error) {
+	res, err := s.db.Exec(`INSERT INTO notes (timestamp, title, text, encrypted) VALUES (?, ?, ?, ?)`,
+		n.Timestamp, n.Title, n.Text, n.Encrypted)
+	if err != nil {
+		return 0, fmt.Errorf("store: add note: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("store: add note: %w", err)
+	}
+	if err := s.setTags(id, n.Tags); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *sqliteIndex) Update(n Note) error {
+	_, err := s.db.Exec(`UPDATE notes SET timestamp = ?, title = ?, text = ?, encrypted = ? WHERE id = ?`,
+		n.Timestamp, n.Title, n.Text, n.Encrypted, n.ID)
+	if err != nil {
+		return fmt.Errorf("store: update note %d: %w", n.ID, err)
+	}
+	if _, err := s.db.Exec(`DELETE FROM tags WHERE note_id = ?`, n.ID); err != nil {
+		return fmt.Errorf("store: update note %d: %w", n.ID, err)
+	}
+	return s.setTags(n.ID, n.Tags)
+}
+
+func (s *sqliteIndex) Remove(id int64) error {
+	if _, err := s.db.Exec(`DELETE FROM notes WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("store: remove note %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *sqliteIndex) Find(query, tag string) ([]Result, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	switch {
+	case query != "" && tag != "":
+		rows, err = s.db.Query(`
+			SELECT n.id, n.timestamp, n.title, n.text, n.encrypted,
+			       snippet(notes_fts, 1, '>>', '<<', '...', 10)
+			FROM notes_fts
+			JOIN notes n ON n.id = notes_fts.rowid
+			JOIN tags t ON t.note_id = n.id
+			WHERE notes_fts MATCH ? AND t.tag = ?
+			ORDER BY rank`, query, tag)
+	case query != "":
+		rows, err = s.db.Query(`
+			SELECT n.id, n.timestamp, n.title, n.text, n.encrypted,
+			       snippet(notes_fts, 1, '>>', '<<', '...', 10)
+			FROM notes_fts
+			JOIN notes n ON n.id = notes_fts.rowid
+			WHERE notes_fts MATCH ?
+			ORDER BY rank`, query)
+	case tag != "":
+		rows, err = s.db.Query(`
+			SELECT n.id, n.timestamp, n.title, n.text, n.encrypted, ''
+			FROM notes n
+			JOIN tags t ON t.note_id = n.id
+			WHERE t.tag = ?
+			ORDER BY n.id`, tag)
+	default:
+		rows, err = s.db.Query(`SELECT id, timestamp, title, text, encrypted, '' FROM notes ORDER BY id`)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("store: find: %w", err)
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var r Result
+		if err := rows.Scan(&r.ID, &r.Timestamp, &r.Title, &r.Text, &r.Encrypted, &r.Snippet); err != nil {
+			return nil, fmt.Errorf("store: find: %w", err)
+		}
+		tags, err := s.tagsFor(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		r.Tags = tags
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// Commit is a no-op for the SQLite backend: every statement above already
+// autocommits.
+func (s *sqliteIndex) Commit() error { return nil }
+
+func (s *sqliteIndex) Close() error { return s.db.Close() }
+
+func (s *sqliteIndex) setTags(noteID int64, tags []string) error {
+	for _, tag := range tags {
+		if _, err := s.db.Exec(`INSERT INTO tags (note_id, tag) VALUES (?, ?)`, noteID, tag); err != nil {
+			return fmt.Errorf("store: set tags for note %d: %w", noteID, err)
+		}
+	}
+	return nil
+}
+
+func (s *sqliteIndex) tagsFor(noteID int64) ([]string, error) {
+	rows, err := s.db.Query(`SELECT tag FROM tags WHERE note_id = ? ORDER BY tag`, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("store: tags for note %d: %w", noteID, err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// ExtractTags pulls #tag tokens out of note text, lowercased and deduplicated.
+// A tag is a run of letters, digits, underscores or hyphens following a '#'
+// that starts a word (so email-style addresses and HTML anchors aren't
+// mistaken for tags).
+func ExtractTags(text string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return r == '\n' || r == '\t' || r == ' '
+	})
+	for _, w := range words {
+		if !strings.HasPrefix(w, "#") {
+			continue
+		}
+		tag := strings.TrimFunc(w[1:], func(r rune) bool {
+			return !(r == '_' || r == '-' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9'))
+		})
+		tag = strings.ToLower(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTest(t *testing.T) NoteIndex {
+	t.Helper()
+	idx, err := Open(filepath.Join(t.TempDir(), "notes.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { idx.Close() })
+	return idx
+}
+
+func TestAddFindUpdateRemove(t *testing.T) {
+	idx := openTest(t)
+
+	id, err := idx.Add(Note{
+		Timestamp: time.Now(),
+		Title:     "Groceries",
+		Text:      "buy milk",
+		Tags:      []string{"home"},
+	})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	results, err := idx.Find("milk", "")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != id {
+		t.Fatalf("Find(%q) = %+v, want one result with ID %d", "milk", results, id)
+	}
+
+	if err := idx.Update(Note{
+		ID:        id,
+		Timestamp: results[0].Timestamp,
+		Title:     "Groceries",
+		Text:      "buy bread",
+		Tags:      []string{"home", "errands"},
+	}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if results, err = idx.Find("milk", ""); err != nil {
+		t.Fatalf("Find after update: %v", err)
+	} else if len(results) != 0 {
+		t.Fatalf("Find(%q) after update = %+v, want no results for the old text", "milk", results)
+	}
+	results, err = idx.Find("bread", "")
+	if err != nil {
+		t.Fatalf("Find after update: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Tags) != 2 {
+		t.Fatalf("Find(%q) after update = %+v, want one result with 2 tags", "bread", results)
+	}
+
+	if err := idx.Remove(id); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if results, err = idx.Find("bread", ""); err != nil {
+		t.Fatalf("Find after remove: %v", err)
+	} else if len(results) != 0 {
+		t.Fatalf("Find after remove = %+v, want none", results)
+	}
+}
+
+func TestFindByTag(t *testing.T) {
+	idx := openTest(t)
+
+	if _, err := idx.Add(Note{Timestamp: time.Now(), Title: "A", Text: "one", Tags: []string{"home"}}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if _, err := idx.Add(Note{Timestamp: time.Now(), Title: "B", Text: "two", Tags: []string{"work"}}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	results, err := idx.Find("", "home")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "A" {
+		t.Fatalf("Find(\"\", %q) = %+v, want only note A", "home", results)
+	}
+}
+
+func TestExtractTags(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"basic", "buy milk #Home and #Errands", []string{"home", "errands"}},
+		{"dedup", "#home stuff #Home again", []string{"home"}},
+		{"none", "nothing tagged here", nil},
+		{"not-an-email", "contact me at foo#bar.com", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := ExtractTags(c.text)
+			if len(got) != len(c.want) {
+				t.Fatalf("ExtractTags(%q) = %v, want %v", c.text, got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Fatalf("ExtractTags(%q) = %v, want %v", c.text, got, c.want)
+				}
+			}
+		})
+	}
+}